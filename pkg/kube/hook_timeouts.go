@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// hookAnnotation is the annotation Helm uses to mark a resource as a hook
+// and declare which phase(s) it runs in, e.g. "pre-install" or the
+// comma-separated "pre-install,pre-upgrade".
+const hookAnnotation = "helm.sh/hook"
+
+// hookPhaseTimeoutAnnotation lets a chart author override the wait timeout
+// for an individual hook resource, regardless of what WaitOptions.
+// PhaseTimeouts says for its phase. Its value is a duration string, e.g.
+// "10m", as accepted by time.ParseDuration.
+const hookPhaseTimeoutAnnotation = "helm.sh/hook-phase-timeout"
+
+// resolveTimeout picks the effective wait timeout for resourceList:
+//
+//  1. a helm.sh/hook-phase-timeout annotation on any resource in the list,
+//  2. opts.PhaseTimeouts[phase], where phase is opts.Phase if the caller set
+//     it, or else whatever helm.sh/hook declares on resourceList,
+//  3. fallback, the timeout the caller originally asked for.
+func resolveTimeout(resourceList ResourceList, fallback time.Duration, opts WaitOptions) time.Duration {
+	timeout := fallback
+
+	phase := opts.Phase
+	if phase == "" {
+		if p, ok := hookPhaseFromAnnotation(resourceList); ok {
+			phase = p
+		}
+	}
+	if phase != "" {
+		if t, ok := opts.PhaseTimeouts[phase]; ok {
+			timeout = t
+		}
+	}
+
+	if t, ok := annotationTimeout(resourceList); ok {
+		timeout = t
+	}
+
+	return timeout
+}
+
+// hookPhaseFromAnnotation returns the first hook phase declared by the
+// helm.sh/hook annotation on any resource in resourceList. A resource may
+// declare more than one phase as a comma-separated list (e.g.
+// "pre-install,pre-upgrade"); the first listed phase is used.
+func hookPhaseFromAnnotation(resourceList ResourceList) (release.HookEvent, bool) {
+	var (
+		phase release.HookEvent
+		found bool
+	)
+
+	_ = resourceList.Visit(func(info *resource.Info, err error) error {
+		if err != nil || found {
+			return nil
+		}
+		accessor, err := meta.Accessor(info.Object)
+		if err != nil {
+			return nil
+		}
+		raw, ok := accessor.GetAnnotations()[hookAnnotation]
+		if !ok || raw == "" {
+			return nil
+		}
+		first := strings.TrimSpace(strings.SplitN(raw, ",", 2)[0])
+		if first == "" {
+			return nil
+		}
+		phase, found = release.HookEvent(first), true
+		return nil
+	})
+
+	return phase, found
+}
+
+// annotationTimeout returns the first hookPhaseTimeoutAnnotation value among
+// resourceList's resources that time.ParseDuration accepts, if any; values
+// it rejects are skipped.
+func annotationTimeout(resourceList ResourceList) (time.Duration, bool) {
+	var (
+		timeout time.Duration
+		found   bool
+	)
+
+	_ = resourceList.Visit(func(info *resource.Info, err error) error {
+		if err != nil || found {
+			return nil
+		}
+		accessor, err := meta.Accessor(info.Object)
+		if err != nil {
+			return nil
+		}
+		raw, ok := accessor.GetAnnotations()[hookPhaseTimeoutAnnotation]
+		if !ok {
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil
+		}
+		timeout, found = d, true
+		return nil
+	})
+
+	return timeout, found
+}
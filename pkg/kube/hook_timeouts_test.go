@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+var jobWithHookAnnotation = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: test
+  namespace: qual
+  annotations:
+    helm.sh/hook: post-install
+`
+
+var jobWithPhaseTimeoutAnnotation = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: test
+  namespace: qual
+  annotations:
+    helm.sh/hook: post-install
+    helm.sh/hook-phase-timeout: 2m
+`
+
+func TestResolveTimeout(t *testing.T) {
+	c := newTestClient(t)
+
+	t.Run("falls back to the caller-supplied timeout", func(t *testing.T) {
+		resourceList, err := c.Build(objBody(unstructuredFromYAML(t, jobNoStatus)), false)
+		require.NoError(t, err)
+
+		assert.Equal(t, 90*time.Second, resolveTimeout(resourceList, 90*time.Second, WaitOptions{}))
+	})
+
+	t.Run("PhaseTimeouts overrides the fallback for the given phase", func(t *testing.T) {
+		resourceList, err := c.Build(objBody(unstructuredFromYAML(t, jobNoStatus)), false)
+		require.NoError(t, err)
+
+		opts := WaitOptions{
+			Phase: release.HookPostInstall,
+			PhaseTimeouts: map[release.HookEvent]time.Duration{
+				release.HookPostInstall: 10 * time.Minute,
+			},
+		}
+		assert.Equal(t, 10*time.Minute, resolveTimeout(resourceList, 90*time.Second, opts))
+	})
+
+	t.Run("a helm.sh/hook-phase-timeout annotation wins over PhaseTimeouts", func(t *testing.T) {
+		resourceList, err := c.Build(objBody(unstructuredFromYAML(t, jobWithPhaseTimeoutAnnotation)), false)
+		require.NoError(t, err)
+
+		opts := WaitOptions{
+			Phase: release.HookPostInstall,
+			PhaseTimeouts: map[release.HookEvent]time.Duration{
+				release.HookPostInstall: 10 * time.Minute,
+			},
+		}
+		assert.Equal(t, 2*time.Minute, resolveTimeout(resourceList, 90*time.Second, opts))
+	})
+
+	t.Run("the phase is derived from the helm.sh/hook annotation when opts.Phase is unset", func(t *testing.T) {
+		resourceList, err := c.Build(objBody(unstructuredFromYAML(t, jobWithHookAnnotation)), false)
+		require.NoError(t, err)
+
+		opts := WaitOptions{
+			PhaseTimeouts: map[release.HookEvent]time.Duration{
+				release.HookPostInstall: 10 * time.Minute,
+			},
+		}
+		assert.Equal(t, 10*time.Minute, resolveTimeout(resourceList, 90*time.Second, opts))
+	})
+}
+
+func TestHookPhaseFromAnnotation(t *testing.T) {
+	c := newTestClient(t)
+
+	resourceList, err := c.Build(objBody(unstructuredFromYAML(t, jobWithHookAnnotation)), false)
+	require.NoError(t, err)
+
+	phase, ok := hookPhaseFromAnnotation(resourceList)
+	require.True(t, ok)
+	assert.Equal(t, release.HookPostInstall, phase)
+
+	resourceList, err = c.Build(objBody(unstructuredFromYAML(t, jobNoStatus)), false)
+	require.NoError(t, err)
+
+	_, ok = hookPhaseFromAnnotation(resourceList)
+	assert.False(t, ok)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"context"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Waiter defines methods related to waiting for resources to achieve
+// a particular state.
+type Waiter interface {
+	// Wait waits up to the given timeout for the resources in resourceList to
+	// be ready. Cancel ctx to stop watching early, e.g. in response to a CLI
+	// SIGINT.
+	Wait(ctx context.Context, resources ResourceList, timeout time.Duration) error
+	// WaitWithJobs is the same as Wait, but will also wait for any Jobs in the ResourceList
+	// to be completed.
+	WaitWithJobs(ctx context.Context, resources ResourceList, timeout time.Duration) error
+	// WaitForDelete waits up to the given timeout for the resources in
+	// resourceList to be deleted. Cancel ctx to stop watching early, e.g. in
+	// response to a CLI SIGINT.
+	WaitForDelete(ctx context.Context, resources ResourceList, timeout time.Duration) error
+
+	// WaitWithResult is like Wait, but returns a *WaitResult reporting the
+	// final status of every resource in resourceList, rather than requiring
+	// callers to parse a joined error to find out which resources blocked
+	// readiness. Cancel ctx to stop watching early, e.g. in response to a
+	// CLI SIGINT.
+	WaitWithResult(ctx context.Context, resources ResourceList, timeout time.Duration, opts WaitOptions) (*WaitResult, error)
+
+	// WaitStream is like WaitWithResult, but reports each resource status
+	// transition on the returned channel as it's observed instead of
+	// blocking until every resource is ready. The channel is closed once
+	// every resource is ready, ctx is done, or the underlying watch fails,
+	// whichever happens first; cancel ctx to stop watching early, e.g. in
+	// response to a CLI SIGINT. On watch failure, the final event sent
+	// before the channel closes has its Err field set -- callers that need
+	// to tell a genuine failure apart from success or cancellation must
+	// check it.
+	WaitStream(ctx context.Context, resources ResourceList, opts WaitOptions) (<-chan WaitEvent, error)
+}
+
+// WaitOptions configures a wait operation driven through WaitWithResult.
+type WaitOptions struct {
+	// WaitForJobs additionally waits for any Jobs among the waited-on
+	// resources to report completion.
+	WaitForJobs bool
+
+	// Phase identifies the hook phase -- pre-install, post-install,
+	// pre-delete, post-delete, pre-upgrade, post-upgrade, test -- that this
+	// wait call is for, if any. It is consulted against PhaseTimeouts to
+	// resolve the effective timeout, letting install/upgrade/rollback/
+	// uninstall give each phase its own wait behavior instead of sharing a
+	// single --timeout across the whole release. Callers that already know
+	// which phase they're waiting for (action.Install and friends, once
+	// they're wired up to pass it) should set this explicitly; it otherwise
+	// falls back to whatever the resources' own helm.sh/hook annotation
+	// declares, via hookPhaseFromAnnotation.
+	Phase release.HookEvent
+
+	// PhaseTimeouts overrides the timeout passed to WaitWithResult for a
+	// given Phase. A chart can further override the resolved value for a
+	// specific hook resource with a `helm.sh/hook-phase-timeout` annotation.
+	PhaseTimeouts map[release.HookEvent]time.Duration
+}
+
+// ReadinessPolicySetter is implemented by Waiters that support overriding
+// their readiness heuristics with a ReadinessPolicy. Action configs type-
+// assert for this interface before calling SetReadinessPolicy, so Waiter
+// implementations that have no notion of per-GVK readiness expressions
+// don't need to implement it.
+type ReadinessPolicySetter interface {
+	// SetReadinessPolicy installs policy, which is consulted for any
+	// resource kstatus does not already consider Current.
+	SetReadinessPolicy(policy *ReadinessPolicy)
+}
@@ -0,0 +1,281 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// kstatusWaiter is a Waiter implementation backed by sigs.k8s.io/cli-utils'
+// kstatus library. Rather than polling, it watches resources and is notified
+// of status updates as they happen.
+type kstatusWaiter struct {
+	sw            watcher.StatusWatcher
+	log           func(string, ...interface{})
+	pausedAsReady bool
+
+	// readinessPolicy, when set, lets callers override kstatus's built-in
+	// readiness heuristics for specific GroupKinds (or, via its fall-through
+	// entry, for every GroupKind) with a CEL expression evaluated against the
+	// live object. See ReadinessPolicy.
+	readinessPolicy *ReadinessPolicy
+}
+
+func (w *kstatusWaiter) wait(ctx context.Context, resourceList ResourceList, timeout time.Duration, waitForJobs bool) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return w.waitForResources(ctx, resourceList, waitForJobs)
+}
+
+func (w *kstatusWaiter) Wait(ctx context.Context, resourceList ResourceList, timeout time.Duration) error {
+	return w.wait(ctx, resourceList, timeout, false)
+}
+
+func (w *kstatusWaiter) WaitWithJobs(ctx context.Context, resourceList ResourceList, timeout time.Duration) error {
+	return w.wait(ctx, resourceList, timeout, true)
+}
+
+// SetReadinessPolicy installs policy as described by ReadinessPolicySetter,
+// satisfying that optional Waiter extension.
+func (w *kstatusWaiter) SetReadinessPolicy(policy *ReadinessPolicy) {
+	w.readinessPolicy = policy
+}
+
+func (w *kstatusWaiter) WaitForDelete(ctx context.Context, resourceList ResourceList, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return w.waitForDelete(ctx, resourceList)
+}
+
+// WaitWithResult is like Wait, but reports a WaitResult describing the
+// final state of every resource instead of requiring callers to parse a
+// joined error for detail.
+func (w *kstatusWaiter) WaitWithResult(ctx context.Context, resourceList ResourceList, timeout time.Duration, opts WaitOptions) (*WaitResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout(resourceList, timeout, opts))
+	defer cancel()
+	return w.watchUntilComplete(ctx, resourceList, opts.WaitForJobs)
+}
+
+func (w *kstatusWaiter) waitForResources(ctx context.Context, resourceList ResourceList, waitForJobs bool) error {
+	result, err := w.watchUntilComplete(ctx, resourceList, waitForJobs)
+	if err != nil {
+		return err
+	}
+	return result.joinedError()
+}
+
+// watchUntilComplete watches resourceList until every resource is ready or
+// ctx is done, whichever comes first, and reports the outcome as a
+// WaitResult.
+func (w *kstatusWaiter) watchUntilComplete(ctx context.Context, resourceList ResourceList, waitForJobs bool) (*WaitResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objs, err := toObjMetadata(resourceList)
+	if err != nil {
+		return nil, err
+	}
+
+	eventCh := w.sw.Watch(ctx, objs, watcher.Options{})
+	statuses := map[object.ObjMetadata]*event.ResourceStatus{}
+
+	for e := range eventCh {
+		if e.Type == event.ErrorEvent {
+			cancel()
+			return nil, e.Error
+		}
+		if e.Type != event.ResourceUpdateEvent {
+			continue
+		}
+
+		rs := e.Resource
+		if w.isReady(rs, waitForJobs) {
+			rs = withStatus(rs, status.CurrentStatus)
+		}
+		statuses[rs.Identifier] = rs
+
+		if allCurrent(statuses, objs) {
+			cancel()
+		}
+	}
+
+	// ctx is cancelled above once every resource is Current, so its error
+	// only means a timeout or caller cancellation when some resource is not.
+	ctxErr := ctx.Err()
+	if allCurrent(statuses, objs) {
+		ctxErr = nil
+	}
+	return newWaitResult(objs, statuses, ctxErr), nil
+}
+
+// withStatus returns a shallow copy of rs with its Status overridden to s.
+// event.ResourceStatus is a plain struct with no DeepCopy method, so this
+// copies by value rather than attempting to call one.
+func withStatus(rs *event.ResourceStatus, s status.Status) *event.ResourceStatus {
+	cp := *rs
+	cp.Status = s
+	return &cp
+}
+
+// deploymentGK identifies apps/Deployment for the pausedAsReady special case
+// in isReady.
+var deploymentGK = schema.GroupKind{Group: "apps", Kind: "Deployment"}
+
+// isReady reports whether rs should be treated as ready even though kstatus's
+// built-in computation did not mark it Current. It consults, in order: the
+// built-in paused-deployment check (only when the caller opted in via
+// pausedAsReady), the ReadinessChecker registry (see
+// RegisterReadinessChecker), and then any ReadinessPolicy CEL expression
+// registered for rs's GroupKind. The registry lookup always runs for every
+// GroupKind, including apps/Deployment, so a RegisterReadinessChecker
+// override for Deployment is never silently shadowed by pausedAsReady.
+func (w *kstatusWaiter) isReady(rs *event.ResourceStatus, waitForJobs bool) bool {
+	if rs.Status == status.CurrentStatus {
+		return true
+	}
+	if rs.Resource == nil {
+		return false
+	}
+
+	if rs.Resource.GetKind() == "Job" && !waitForJobs {
+		return true
+	}
+
+	gk := rs.Resource.GroupVersionKind().GroupKind()
+
+	if gk == deploymentGK && w.pausedAsReady {
+		if ready, reason, err := checkDeploymentPaused(rs.Resource); err != nil {
+			w.log("paused-deployment check for %q failed: %v", rs.Resource.GetName(), err)
+		} else if ready {
+			return true
+		} else if reason != "" {
+			w.log("%s %q: %s", gk, rs.Resource.GetName(), reason)
+		}
+	}
+
+	if checker, ok := lookupReadinessChecker(gk); ok {
+		ready, reason, err := checker(rs.Resource)
+		if err != nil {
+			w.log("readiness checker for %s %q failed: %v", gk, rs.Resource.GetName(), err)
+		} else if ready {
+			return true
+		} else if reason != "" {
+			w.log("%s %q: %s", gk, rs.Resource.GetName(), reason)
+		}
+	}
+
+	if ready, err := w.evalReadinessPolicy(rs.Resource); err == nil && ready {
+		return true
+	}
+
+	return false
+}
+
+// evalReadinessPolicy evaluates the CEL program registered for obj's
+// GroupKind (falling through to the policy's Default program, if any)
+// against obj and returns its boolean result. It returns false, nil if no
+// policy applies to obj.
+func (w *kstatusWaiter) evalReadinessPolicy(obj *unstructured.Unstructured) (bool, error) {
+	if w.readinessPolicy == nil {
+		return false, nil
+	}
+	return w.readinessPolicy.Eval(obj)
+}
+
+func allCurrent(statuses map[object.ObjMetadata]*event.ResourceStatus, objs []object.ObjMetadata) bool {
+	if len(statuses) < len(objs) {
+		return false
+	}
+	for _, id := range objs {
+		rs, ok := statuses[id]
+		if !ok || rs.Status != status.CurrentStatus {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *kstatusWaiter) waitForDelete(ctx context.Context, resourceList ResourceList) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objs, err := toObjMetadata(resourceList)
+	if err != nil {
+		return err
+	}
+
+	eventCh := w.sw.Watch(ctx, objs, watcher.Options{})
+	deleted := map[object.ObjMetadata]bool{}
+
+	for e := range eventCh {
+		if e.Type == event.ErrorEvent {
+			cancel()
+			return e.Error
+		}
+		if e.Type != event.ResourceUpdateEvent {
+			continue
+		}
+		if e.Resource.Status == status.NotFoundStatus {
+			deleted[e.Resource.Identifier] = true
+		}
+		if len(deleted) == len(objs) {
+			cancel()
+		}
+	}
+
+	if len(deleted) == len(objs) {
+		return nil
+	}
+
+	var errs []error
+	for _, id := range objs {
+		if !deleted[id] {
+			errs = append(errs, fmt.Errorf("%s: %s still present", id.Name, id.GroupKind.Kind))
+		}
+	}
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+	return errors.Join(errs...)
+}
+
+func toObjMetadata(resourceList ResourceList) ([]object.ObjMetadata, error) {
+	objs := make([]object.ObjMetadata, 0, len(resourceList))
+	err := resourceList.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		obj, err := object.RuntimeToObjMeta(info.Object)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, obj)
+		return nil
+	})
+	return objs, err
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package kube // import "helm.sh/helm/v3/pkg/kube"
 
 import (
+	"context"
 	"errors"
 	"log"
 	"testing"
@@ -34,6 +35,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/yaml"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
@@ -194,7 +197,7 @@ func TestKWaitJob(t *testing.T) {
 				resourceList = append(resourceList, list...)
 			}
 
-			err := kwaiter.wait(resourceList, time.Second*3, tt.waitForJobs)
+			err := kwaiter.wait(context.Background(), resourceList, time.Second*3, tt.waitForJobs)
 			if tt.expectErrs != nil {
 				assert.EqualError(t, err, errors.Join(tt.expectErrs...).Error())
 				return
@@ -203,3 +206,129 @@ func TestKWaitJob(t *testing.T) {
 		})
 	}
 }
+
+// TestIsReadyRegistryOverrideNotGatedByPausedAsReady proves that a
+// RegisterReadinessChecker override for apps/Deployment runs regardless of
+// pausedAsReady -- i.e. the pausedAsReady gate only ever applies to the
+// built-in paused-deployment check, never to the whole GroupKind.
+func TestIsReadyRegistryOverrideNotGatedByPausedAsReady(t *testing.T) {
+	gk := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	RegisterReadinessChecker(gk, func(*unstructured.Unstructured) (bool, string, error) {
+		return true, "", nil
+	})
+	t.Cleanup(func() {
+		readinessCheckersMu.Lock()
+		delete(readinessCheckers, gk)
+		readinessCheckersMu.Unlock()
+	})
+
+	resource := unstructuredFromYAML(t, pausedDeploymentYaml)
+	resource.SetAnnotations(nil)
+	require.NoError(t, unstructured.SetNestedField(resource.Object, false, "spec", "paused"))
+
+	kwaiter := kstatusWaiter{log: log.Printf, pausedAsReady: false}
+	rs := &event.ResourceStatus{Status: status.InProgressStatus, Resource: resource}
+
+	assert.True(t, kwaiter.isReady(rs, false))
+}
+
+func TestKWaitReadinessPolicy(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t)
+	fakeClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+	fakeMapper := testutil.NewFakeRESTMapper(
+		v1.SchemeGroupVersion.WithKind("Pod"),
+	)
+	statusWatcher := watcher.NewDefaultStatusWatcher(fakeClient, fakeMapper)
+
+	m := make(map[string]interface{})
+	require.NoError(t, yaml.Unmarshal([]byte(podNoStatus), &m))
+	resource := &unstructured.Unstructured{Object: m}
+	gvr := getGVR(t, fakeMapper, resource)
+	require.NoError(t, fakeClient.Tracker().Create(gvr, resource, resource.GetNamespace()))
+
+	policy := NewReadinessPolicy()
+	require.NoError(t, policy.Add(resource.GroupVersionKind().GroupKind(), "self.metadata.name == 'in-progress-pod'"))
+
+	kwaiter := kstatusWaiter{
+		sw:              statusWatcher,
+		log:             log.Printf,
+		readinessPolicy: policy,
+	}
+
+	resourceList, err := c.Build(objBody(resource), false)
+	require.NoError(t, err)
+
+	assert.NoError(t, kwaiter.wait(context.Background(), resourceList, time.Second*3, false))
+}
+
+// TestSetReadinessPolicy exercises SetReadinessPolicy through the
+// ReadinessPolicySetter interface, the way a caller holding only a Waiter
+// would have to, rather than reaching into kstatusWaiter's unexported
+// field directly as TestKWaitReadinessPolicy does.
+func TestSetReadinessPolicy(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t)
+	fakeClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+	fakeMapper := testutil.NewFakeRESTMapper(
+		v1.SchemeGroupVersion.WithKind("Pod"),
+	)
+	statusWatcher := watcher.NewDefaultStatusWatcher(fakeClient, fakeMapper)
+
+	m := make(map[string]interface{})
+	require.NoError(t, yaml.Unmarshal([]byte(podNoStatus), &m))
+	resource := &unstructured.Unstructured{Object: m}
+	gvr := getGVR(t, fakeMapper, resource)
+	require.NoError(t, fakeClient.Tracker().Create(gvr, resource, resource.GetNamespace()))
+
+	var waiter Waiter = &kstatusWaiter{sw: statusWatcher, log: log.Printf}
+
+	resourceList, err := c.Build(objBody(resource), false)
+	require.NoError(t, err)
+
+	// Without a policy installed, the pod never reaches Current and the
+	// wait times out.
+	require.Error(t, waiter.Wait(context.Background(), resourceList, time.Second))
+
+	setter, ok := waiter.(ReadinessPolicySetter)
+	require.True(t, ok, "kstatusWaiter must implement ReadinessPolicySetter")
+
+	policy := NewReadinessPolicy()
+	require.NoError(t, policy.Add(resource.GroupVersionKind().GroupKind(), "self.metadata.name == 'in-progress-pod'"))
+	setter.SetReadinessPolicy(policy)
+
+	assert.NoError(t, waiter.Wait(context.Background(), resourceList, time.Second*3))
+}
+
+func TestKWaitWithResult(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t)
+	fakeClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+	fakeMapper := testutil.NewFakeRESTMapper(
+		batchv1.SchemeGroupVersion.WithKind("Job"),
+	)
+	statusWatcher := watcher.NewDefaultStatusWatcher(fakeClient, fakeMapper)
+
+	m := make(map[string]interface{})
+	require.NoError(t, yaml.Unmarshal([]byte(jobNoStatus), &m))
+	resource := &unstructured.Unstructured{Object: m}
+	gvr := getGVR(t, fakeMapper, resource)
+	require.NoError(t, fakeClient.Tracker().Create(gvr, resource, resource.GetNamespace()))
+
+	kwaiter := kstatusWaiter{sw: statusWatcher, log: log.Printf}
+
+	resourceList, err := c.Build(objBody(resource), false)
+	require.NoError(t, err)
+
+	result, err := kwaiter.WaitWithResult(context.Background(), resourceList, time.Second*3, WaitOptions{WaitForJobs: true})
+	require.NoError(t, err)
+	require.False(t, result.Ready())
+	require.Len(t, result.Resources, 1)
+	assert.Equal(t, "test", result.Resources[0].Name)
+	assert.Equal(t, "Job", result.Resources[0].Kind)
+	assert.NotEqual(t, status.CurrentStatus.String(), result.Resources[0].Status)
+	assert.True(t, result.TimedOut)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ReadinessCheckerFunc reports whether obj has reached a ready state. reason
+// is a short human-readable explanation that the waiter logs; it does not
+// appear in wait errors or in WaitResult, and it is not required when ready
+// is true. Returning a non-nil err
+// causes the waiter to log the error and continue waiting rather than
+// treating obj as ready.
+type ReadinessCheckerFunc func(obj *unstructured.Unstructured) (ready bool, reason string, err error)
+
+var (
+	readinessCheckersMu sync.RWMutex
+	readinessCheckers   = map[schema.GroupKind]ReadinessCheckerFunc{}
+)
+
+// RegisterReadinessChecker installs fn as the readiness checker for gk,
+// replacing any checker (built-in or otherwise) previously registered for
+// that GroupKind. It is safe to call concurrently and is typically called
+// from an init function by plugins that want Helm's waiters to understand
+// the readiness of a CRD Helm has no built-in support for.
+func RegisterReadinessChecker(gk schema.GroupKind, fn ReadinessCheckerFunc) {
+	readinessCheckersMu.Lock()
+	defer readinessCheckersMu.Unlock()
+	readinessCheckers[gk] = fn
+}
+
+// lookupReadinessChecker returns the checker registered for gk, if any.
+func lookupReadinessChecker(gk schema.GroupKind) (ReadinessCheckerFunc, bool) {
+	readinessCheckersMu.RLock()
+	defer readinessCheckersMu.RUnlock()
+	fn, ok := readinessCheckers[gk]
+	return fn, ok
+}
+
+func init() {
+	RegisterReadinessChecker(schema.GroupKind{Kind: "Pod"}, checkPodReady)
+	RegisterReadinessChecker(schema.GroupKind{Group: "batch", Kind: "Job"}, checkJobReady)
+	RegisterReadinessChecker(schema.GroupKind{Group: "apps", Kind: "StatefulSet"}, checkStatefulSetReady)
+	RegisterReadinessChecker(schema.GroupKind{Group: "apps", Kind: "DaemonSet"}, checkDaemonSetReady)
+	RegisterReadinessChecker(schema.GroupKind{Kind: "PersistentVolumeClaim"}, checkPVCReady)
+	RegisterReadinessChecker(schema.GroupKind{Kind: "Service"}, checkServiceReady)
+
+	RegisterReadinessChecker(schema.GroupKind{Group: "cert-manager.io", Kind: "Certificate"}, checkCertificateReady)
+	RegisterReadinessChecker(schema.GroupKind{Group: "argoproj.io", Kind: "Rollout"}, checkRolloutReady)
+	RegisterReadinessChecker(schema.GroupKind{Group: "networking.istio.io", Kind: "VirtualService"}, checkVirtualServiceReady)
+	RegisterReadinessChecker(schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}, checkCRDEstablished)
+}
+
+func checkPodReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if conditionStatus(obj.Object, "Ready") == "True" {
+		return true, "", nil
+	}
+	return false, "pod is not Ready", nil
+}
+
+func checkJobReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if conditionStatus(obj.Object, "Complete") == "True" {
+		return true, "", nil
+	}
+	completions, found, _ := unstructured.NestedInt64(obj.Object, "spec", "completions")
+	if !found {
+		completions = 1
+	}
+	succeeded, _, _ := unstructured.NestedInt64(obj.Object, "status", "succeeded")
+	if succeeded >= completions {
+		return true, "", nil
+	}
+	return false, "job has not completed", nil
+}
+
+// checkDeploymentPaused reports a Deployment as ready when it is paused.
+// This implements the waiter's pausedAsReady option: a paused rollout can
+// never converge, so treating it as blocking would wait out the full
+// timeout for no reason. It is called directly from kstatusWaiter.isReady
+// rather than through the ReadinessChecker registry, so that a
+// RegisterReadinessChecker override for apps/Deployment always runs
+// regardless of pausedAsReady instead of being shadowed by it.
+func checkDeploymentPaused(obj *unstructured.Unstructured) (bool, string, error) {
+	paused, found, err := unstructured.NestedBool(obj.Object, "spec", "paused")
+	if err != nil {
+		return false, "", err
+	}
+	if found && paused {
+		return true, "deployment is paused", nil
+	}
+	return false, "deployment is progressing", nil
+}
+
+func checkStatefulSetReady(obj *unstructured.Unstructured) (bool, string, error) {
+	replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+	if ready >= replicas {
+		return true, "", nil
+	}
+	return false, "statefulset has not reached the desired ready replica count", nil
+}
+
+func checkDaemonSetReady(obj *unstructured.Unstructured) (bool, string, error) {
+	desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+	if ready >= desired {
+		return true, "", nil
+	}
+	return false, "daemonset has not reached the desired ready count", nil
+}
+
+func checkPVCReady(obj *unstructured.Unstructured) (bool, string, error) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	if phase == "Bound" {
+		return true, "", nil
+	}
+	return false, "persistentvolumeclaim is not Bound", nil
+}
+
+func checkServiceReady(obj *unstructured.Unstructured) (bool, string, error) {
+	svcType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+	if svcType != "LoadBalancer" {
+		return true, "", nil
+	}
+	ingress, _, _ := unstructured.NestedSlice(obj.Object, "status", "loadBalancer", "ingress")
+	if len(ingress) > 0 {
+		return true, "", nil
+	}
+	return false, "loadbalancer has not been assigned an ingress address", nil
+}
+
+func checkCertificateReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if conditionStatus(obj.Object, "Ready") == "True" {
+		return true, "", nil
+	}
+	return false, "certificate is not Ready", nil
+}
+
+func checkRolloutReady(obj *unstructured.Unstructured) (bool, string, error) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	if phase == "Healthy" {
+		return true, "", nil
+	}
+	return false, "rollout phase is " + phase, nil
+}
+
+// checkVirtualServiceReady always reports readiness: Istio's VirtualService
+// has no status condition to converge on -- it takes effect as soon as it
+// is accepted by the config store.
+func checkVirtualServiceReady(_ *unstructured.Unstructured) (bool, string, error) {
+	return true, "", nil
+}
+
+func checkCRDEstablished(obj *unstructured.Unstructured) (bool, string, error) {
+	if conditionStatus(obj.Object, "Established") == "True" {
+		return true, "", nil
+	}
+	return false, "customresourcedefinition is not Established", nil
+}
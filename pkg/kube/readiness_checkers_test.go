@@ -0,0 +1,163 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func unstructuredFromYAML(t *testing.T, y string) *unstructured.Unstructured {
+	t.Helper()
+	m := make(map[string]interface{})
+	require.NoError(t, yaml.Unmarshal([]byte(y), &m))
+	return &unstructured.Unstructured{Object: m}
+}
+
+var crdNotEstablished = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: certificates.cert-manager.io
+status:
+  conditions:
+  - type: NamesAccepted
+    status: "True"
+`
+
+var crdEstablished = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: certificates.cert-manager.io
+status:
+  conditions:
+  - type: NamesAccepted
+    status: "True"
+  - type: Established
+    status: "True"
+`
+
+func TestCheckCRDEstablished(t *testing.T) {
+	ready, _, err := checkCRDEstablished(unstructuredFromYAML(t, crdNotEstablished))
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	ready, _, err = checkCRDEstablished(unstructuredFromYAML(t, crdEstablished))
+	require.NoError(t, err)
+	assert.True(t, ready)
+}
+
+var jobMultiCompletionPartial = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  namespace: ns
+spec:
+  completions: 3
+status:
+  succeeded: 1
+`
+
+var jobMultiCompletionDone = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  namespace: ns
+spec:
+  completions: 3
+status:
+  succeeded: 3
+`
+
+func TestCheckJobReady(t *testing.T) {
+	ready, _, err := checkJobReady(unstructuredFromYAML(t, jobNoStatus))
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	ready, _, err = checkJobReady(unstructuredFromYAML(t, jobComplete))
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	// A Job with completions > 1 is not ready just because one pod has
+	// succeeded -- succeeded must reach completions.
+	ready, _, err = checkJobReady(unstructuredFromYAML(t, jobMultiCompletionPartial))
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	ready, _, err = checkJobReady(unstructuredFromYAML(t, jobMultiCompletionDone))
+	require.NoError(t, err)
+	assert.True(t, ready)
+}
+
+var rolloutProgressing = `
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: canary
+status:
+  phase: Progressing
+`
+
+var rolloutHealthy = `
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: canary
+status:
+  phase: Healthy
+`
+
+func TestCheckRolloutReady(t *testing.T) {
+	ready, reason, err := checkRolloutReady(unstructuredFromYAML(t, rolloutProgressing))
+	require.NoError(t, err)
+	assert.False(t, ready)
+	assert.Equal(t, "rollout phase is Progressing", reason)
+
+	ready, _, err = checkRolloutReady(unstructuredFromYAML(t, rolloutHealthy))
+	require.NoError(t, err)
+	assert.True(t, ready)
+}
+
+func TestRegisterReadinessChecker(t *testing.T) {
+	gk := schema.GroupKind{Group: "example.com", Kind: "Widget"}
+	RegisterReadinessChecker(gk, func(obj *unstructured.Unstructured) (bool, string, error) {
+		return obj.GetName() == "ready-widget", "", nil
+	})
+
+	checker, ok := lookupReadinessChecker(gk)
+	require.True(t, ok)
+
+	ready, _, err := checker(&unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "ready-widget"},
+	}})
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	ready, _, err = checker(&unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "other-widget"},
+	}})
+	require.NoError(t, err)
+	assert.False(t, ready)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// ReadinessPolicy lets callers extend (or override) kstatus's built-in
+// Pod/Job/Deployment readiness heuristics with CEL expressions evaluated
+// against the live object. It is most useful for CRDs -- such as
+// cert-manager's Certificate, Argo's Rollout, or Strimzi's Kafka -- whose
+// readiness Helm has no built-in notion of.
+//
+// Expressions receive the resource's unstructured content as the `self`
+// variable and must return a bool: true means the resource should be
+// considered Current regardless of what kstatus computed for it.
+type ReadinessPolicy struct {
+	// ByGroupKind holds one compiled CEL program per GroupKind.
+	ByGroupKind map[schema.GroupKind]cel.Program
+	// Default, when set, is evaluated for any GroupKind that has no more
+	// specific entry in ByGroupKind.
+	Default cel.Program
+}
+
+// NewReadinessPolicy returns an empty ReadinessPolicy ready to have
+// expressions added to it with Add and AddDefault.
+func NewReadinessPolicy() *ReadinessPolicy {
+	return &ReadinessPolicy{ByGroupKind: map[schema.GroupKind]cel.Program{}}
+}
+
+// Add compiles expr and registers it as the readiness expression for gk.
+func (p *ReadinessPolicy) Add(gk schema.GroupKind, expr string) error {
+	prog, err := compileReadinessExpr(expr)
+	if err != nil {
+		return fmt.Errorf("compiling readiness expression for %s: %w", gk, err)
+	}
+	p.ByGroupKind[gk] = prog
+	return nil
+}
+
+// AddDefault compiles expr and registers it as the fall-through readiness
+// expression used for any GroupKind without a more specific entry.
+func (p *ReadinessPolicy) AddDefault(expr string) error {
+	prog, err := compileReadinessExpr(expr)
+	if err != nil {
+		return fmt.Errorf("compiling default readiness expression: %w", err)
+	}
+	p.Default = prog
+	return nil
+}
+
+// Eval runs the CEL program registered for obj's GroupKind -- or, absent
+// one, the Default program -- against obj. It returns false, nil if no
+// program applies to obj.
+func (p *ReadinessPolicy) Eval(obj *unstructured.Unstructured) (bool, error) {
+	prog, ok := p.ByGroupKind[obj.GroupVersionKind().GroupKind()]
+	if !ok {
+		prog = p.Default
+	}
+	if prog == nil {
+		return false, nil
+	}
+
+	out, _, err := prog.Eval(map[string]interface{}{"self": obj.Object})
+	if err != nil {
+		return false, fmt.Errorf("evaluating readiness expression: %w", err)
+	}
+	b, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("readiness expression must return a bool, got %s", out.Type())
+	}
+	return b, nil
+}
+
+// compileReadinessExpr builds the CEL environment readiness expressions run
+// in -- `self` bound to the resource's unstructured content, plus the
+// isHealthy/hasCondition/jsonpath helpers -- and compiles expr against it.
+func compileReadinessExpr(expr string) (cel.Program, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("self", cel.DynType),
+		cel.Function("isHealthy",
+			cel.Overload("isHealthy_self",
+				[]*cel.Type{cel.DynType}, cel.BoolType,
+				cel.UnaryBinding(celIsHealthy))),
+		cel.Function("hasCondition",
+			cel.Overload("hasCondition_self_type_status",
+				[]*cel.Type{cel.DynType, cel.StringType, cel.StringType}, cel.BoolType,
+				cel.FunctionBinding(celHasCondition))),
+		cel.Function("jsonpath",
+			cel.Overload("jsonpath_self_path",
+				[]*cel.Type{cel.DynType, cel.StringType}, cel.StringType,
+				cel.BinaryBinding(celJSONPath))),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, iss := env.Compile(expr)
+	if iss != nil && iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("readiness expression %q must return bool, got %s", expr, ast.OutputType())
+	}
+	return env.Program(ast)
+}
+
+// celIsHealthy implements the isHealthy(self) CEL helper. An object is
+// considered healthy if it carries a "Ready" or "Available" condition whose
+// status is "True".
+func celIsHealthy(val ref.Val) ref.Val {
+	obj, ok := asMap(val)
+	if !ok {
+		return types.Bool(false)
+	}
+	for _, t := range []string{"Ready", "Available"} {
+		if conditionStatus(obj, t) == "True" {
+			return types.Bool(true)
+		}
+	}
+	return types.Bool(false)
+}
+
+// celHasCondition implements the hasCondition(self, type, status) CEL
+// helper, reporting whether self carries a status.conditions entry matching
+// both type and status.
+func celHasCondition(vals ...ref.Val) ref.Val {
+	if len(vals) != 3 {
+		return types.Bool(false)
+	}
+	obj, ok := asMap(vals[0])
+	if !ok {
+		return types.Bool(false)
+	}
+	condType, ok1 := vals[1].Value().(string)
+	condStatus, ok2 := vals[2].Value().(string)
+	if !ok1 || !ok2 {
+		return types.Bool(false)
+	}
+	return types.Bool(conditionStatus(obj, condType) == condStatus)
+}
+
+// celJSONPath implements the jsonpath(self, path) CEL helper, returning the
+// string form of the first value matched by a standard Kubernetes JSONPath
+// expression, or "" if nothing matched.
+func celJSONPath(lhs, rhs ref.Val) ref.Val {
+	obj, ok := asMap(lhs)
+	if !ok {
+		return types.String("")
+	}
+	path, ok := rhs.Value().(string)
+	if !ok {
+		return types.String("")
+	}
+
+	jp := jsonpath.New("readinessPolicy")
+	if err := jp.Parse(path); err != nil {
+		return types.String("")
+	}
+	results, err := jp.FindResults(obj)
+	if err != nil || len(results) == 0 || len(results[0]) == 0 {
+		return types.String("")
+	}
+	return types.String(fmt.Sprintf("%v", results[0][0].Interface()))
+}
+
+func asMap(val ref.Val) (map[string]interface{}, bool) {
+	m, ok := val.Value().(map[string]interface{})
+	return m, ok
+}
+
+// conditionStatus returns the "status" field of the first entry in
+// obj.status.conditions whose "type" field equals condType, or "" if none
+// matches.
+func conditionStatus(obj map[string]interface{}, condType string) string {
+	status, ok := obj["status"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	conditions, ok := status["conditions"].([]interface{})
+	if !ok {
+		return ""
+	}
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if t, _ := cond["type"].(string); t == condType {
+			s, _ := cond["status"].(string)
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var certificateReady = `
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: example-com
+status:
+  conditions:
+  - type: Ready
+    status: "True"
+    reason: Ready
+`
+
+var certificateNotReady = `
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: example-com
+status:
+  conditions:
+  - type: Ready
+    status: "False"
+    reason: Issuing
+`
+
+func evalExpr(t *testing.T, expr, yaml string) bool {
+	t.Helper()
+	prog, err := compileReadinessExpr(expr)
+	require.NoError(t, err)
+
+	obj := unstructuredFromYAML(t, yaml)
+	out, _, err := prog.Eval(map[string]interface{}{"self": obj.Object})
+	require.NoError(t, err)
+
+	b, ok := out.Value().(bool)
+	require.True(t, ok, "expression must evaluate to a bool")
+	return b
+}
+
+func TestCompileReadinessExprIsHealthy(t *testing.T) {
+	assert.True(t, evalExpr(t, "isHealthy(self)", certificateReady))
+	assert.False(t, evalExpr(t, "isHealthy(self)", certificateNotReady))
+}
+
+func TestCompileReadinessExprHasCondition(t *testing.T) {
+	assert.True(t, evalExpr(t, `hasCondition(self, "Ready", "True")`, certificateReady))
+	assert.False(t, evalExpr(t, `hasCondition(self, "Ready", "True")`, certificateNotReady))
+	assert.True(t, evalExpr(t, `hasCondition(self, "Ready", "False")`, certificateNotReady))
+}
+
+func TestCompileReadinessExprJSONPath(t *testing.T) {
+	assert.True(t, evalExpr(t, `jsonpath(self, '{.status.conditions[0].reason}') == "Ready"`, certificateReady))
+	assert.True(t, evalExpr(t, `jsonpath(self, '{.status.conditions[0].reason}') == "Issuing"`, certificateNotReady))
+	assert.False(t, evalExpr(t, `jsonpath(self, '{.status.conditions[0].reason}') == "Ready"`, certificateNotReady))
+}
@@ -0,0 +1,167 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// WaitResult is the JSON-serializable outcome of a wait operation. It
+// reports every waited-on resource's final status, so a caller can show
+// exactly which resources are blocking a release rather than parsing a
+// joined error string. It is intended to back a future CLI `--wait-report`
+// flag, but no such flag exists yet -- WaitWithResult is currently reachable
+// only as a pkg/kube API, with no command wired up to print WaitResult.
+type WaitResult struct {
+	// Resources holds one entry per waited-on resource, in the order the
+	// resources were passed to the wait call.
+	Resources []ResourceStatus `json:"resources"`
+	// TimedOut is true if the wait ended because its context deadline was
+	// exceeded or was cancelled, rather than because every resource became
+	// ready.
+	TimedOut bool `json:"timedOut,omitempty"`
+
+	// ctxErr is the context error that ended the wait, if TimedOut is true.
+	// It is unexported since it isn't meaningfully JSON-serializable; use
+	// TimedOut to detect this case from serialized output.
+	ctxErr error
+}
+
+// ResourceStatus is the final observed status of a single resource at the
+// end of a wait operation.
+type ResourceStatus struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	Group      string `json:"group,omitempty"`
+	Kind       string `json:"kind"`
+	Generation int64  `json:"generation,omitempty"`
+	// Status is one of kstatus's status strings: Current, InProgress,
+	// Failed, Terminating, NotFound, or Unknown.
+	Status     string            `json:"status"`
+	Message    string            `json:"message,omitempty"`
+	Conditions []StatusCondition `json:"conditions,omitempty"`
+}
+
+// StatusCondition mirrors a Kubernetes status condition.
+type StatusCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Ready reports whether every resource in the result reached kstatus's
+// Current status before the wait ended.
+func (r *WaitResult) Ready() bool {
+	if r.TimedOut {
+		return false
+	}
+	for _, rs := range r.Resources {
+		if rs.Status != status.CurrentStatus.String() {
+			return false
+		}
+	}
+	return true
+}
+
+// joinedError reconstructs the single joined error historically returned by
+// kstatusWaiter.wait, for callers that haven't moved to WaitWithResult.
+func (r *WaitResult) joinedError() error {
+	var errs []error
+	for _, rs := range r.Resources {
+		if rs.Status == status.CurrentStatus.String() {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", rs.Name, rs.Message))
+	}
+	if r.ctxErr != nil {
+		errs = append(errs, r.ctxErr)
+	}
+	return errors.Join(errs...)
+}
+
+func newWaitResult(objs []object.ObjMetadata, statuses map[object.ObjMetadata]*event.ResourceStatus, ctxErr error) *WaitResult {
+	result := &WaitResult{
+		Resources: make([]ResourceStatus, 0, len(objs)),
+		TimedOut:  ctxErr != nil,
+		ctxErr:    ctxErr,
+	}
+	for _, id := range objs {
+		rs, ok := statuses[id]
+		if !ok {
+			result.Resources = append(result.Resources, ResourceStatus{
+				Name:    id.Name,
+				Group:   id.GroupKind.Group,
+				Kind:    id.GroupKind.Kind,
+				Status:  status.UnknownStatus.String(),
+				Message: fmt.Sprintf("%s not ready, status: %s", id.GroupKind.Kind, status.UnknownStatus),
+			})
+			continue
+		}
+		result.Resources = append(result.Resources, resourceStatusFromEvent(id, rs))
+	}
+	return result
+}
+
+func resourceStatusFromEvent(id object.ObjMetadata, rs *event.ResourceStatus) ResourceStatus {
+	out := ResourceStatus{
+		Name:   id.Name,
+		Group:  id.GroupKind.Group,
+		Kind:   id.GroupKind.Kind,
+		Status: rs.Status.String(),
+	}
+	if rs.Status != status.CurrentStatus {
+		out.Message = fmt.Sprintf("%s not ready, status: %s", id.GroupKind.Kind, rs.Status)
+	}
+	if rs.Resource != nil {
+		out.Namespace = rs.Resource.GetNamespace()
+		out.Generation = rs.Resource.GetGeneration()
+		out.Conditions = extractConditions(rs.Resource)
+	}
+	return out
+}
+
+func extractConditions(obj *unstructured.Unstructured) []StatusCondition {
+	raw, found, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil || !found {
+		return nil
+	}
+	conditions := make([]StatusCondition, 0, len(raw))
+	for _, c := range raw {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _ := cond["type"].(string)
+		condStatus, _ := cond["status"].(string)
+		reason, _ := cond["reason"].(string)
+		message, _ := cond["message"].(string)
+		conditions = append(conditions, StatusCondition{
+			Type:    condType,
+			Status:  condStatus,
+			Reason:  reason,
+			Message: message,
+		})
+	}
+	return conditions
+}
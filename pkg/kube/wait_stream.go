@@ -0,0 +1,135 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// WaitEvent reports a single resource status transition observed while
+// streaming a wait with WaitStream.
+type WaitEvent struct {
+	// Resource is the resource's status at the time of this event.
+	Resource ResourceStatus `json:"resource"`
+	// PreviousStatus is the resource's status before this transition, or ""
+	// if this is the first event observed for the resource.
+	PreviousStatus string `json:"previousStatus,omitempty"`
+	// CurrentStatus is the resource's status as of this event. It is always
+	// equal to Resource.Status.
+	CurrentStatus string `json:"currentStatus"`
+	// Message is a short human-readable description of the transition.
+	Message   string    `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// Err is set on the final event sent before the channel closes if the
+	// wait ended because the underlying watch failed, rather than because
+	// every resource became ready or ctx was done. Callers must check this
+	// field to distinguish a genuine watch error from a successful or
+	// cancelled wait, since the channel closes the same way in all three
+	// cases.
+	Err error `json:"-"`
+}
+
+// WaitStream watches resourceList and publishes a WaitEvent for every
+// resource status transition it observes, until every resource is ready, ctx
+// is done, or the underlying watch fails. In the last case, the final event
+// sent before the channel closes has Err set; callers that need to
+// distinguish failure from success or cancellation must check it.
+func (w *kstatusWaiter) WaitStream(ctx context.Context, resourceList ResourceList, opts WaitOptions) (<-chan WaitEvent, error) {
+	objs, err := toObjMetadata(resourceList)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	eventCh := w.sw.Watch(ctx, objs, watcher.Options{})
+	out := make(chan WaitEvent)
+
+	go func() {
+		defer cancel()
+		defer close(out)
+
+		statuses := map[object.ObjMetadata]*event.ResourceStatus{}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-eventCh:
+				if !ok {
+					return
+				}
+				if e.Type == event.ErrorEvent {
+					select {
+					case out <- WaitEvent{Err: e.Error, Timestamp: time.Now()}:
+					case <-ctx.Done():
+					}
+					return
+				}
+				if e.Type != event.ResourceUpdateEvent {
+					continue
+				}
+
+				rs := e.Resource
+				if w.isReady(rs, opts.WaitForJobs) {
+					rs = withStatus(rs, status.CurrentStatus)
+				}
+
+				previous := statuses[rs.Identifier]
+				statuses[rs.Identifier] = rs
+
+				var previousStatus string
+				if previous != nil {
+					if previous.Status == rs.Status {
+						// No transition to report.
+						if allCurrent(statuses, objs) {
+							return
+						}
+						continue
+					}
+					previousStatus = previous.Status.String()
+				}
+
+				resourceStatus := resourceStatusFromEvent(rs.Identifier, rs)
+				wEvent := WaitEvent{
+					Resource:       resourceStatus,
+					PreviousStatus: previousStatus,
+					CurrentStatus:  resourceStatus.Status,
+					Message:        resourceStatus.Message,
+					Timestamp:      time.Now(),
+				}
+
+				select {
+				case out <- wEvent:
+				case <-ctx.Done():
+					return
+				}
+
+				if allCurrent(statuses, objs) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+// erroringStatusWatcher is a watcher.StatusWatcher stub that immediately
+// reports a watch failure, for exercising WaitStream's error-signaling path
+// without needing the fake dynamic client to simulate one.
+type erroringStatusWatcher struct {
+	err error
+}
+
+func (w erroringStatusWatcher) Watch(_ context.Context, _ object.ObjMetadataSet, _ watcher.Options) <-chan event.Event {
+	ch := make(chan event.Event, 1)
+	ch <- event.Event{Type: event.ErrorEvent, Error: w.err}
+	close(ch)
+	return ch
+}
+
+func TestKWaitStream(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t)
+	fakeClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+	fakeMapper := testutil.NewFakeRESTMapper(
+		batchv1.SchemeGroupVersion.WithKind("Job"),
+	)
+	statusWatcher := watcher.NewDefaultStatusWatcher(fakeClient, fakeMapper)
+
+	job := unstructuredFromYAML(t, jobNoStatus)
+	gvr := getGVR(t, fakeMapper, job)
+	require.NoError(t, fakeClient.Tracker().Create(gvr, job, job.GetNamespace()))
+
+	kwaiter := kstatusWaiter{sw: statusWatcher, log: log.Printf}
+
+	resourceList, err := c.Build(objBody(job), false)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := kwaiter.WaitStream(ctx, resourceList, WaitOptions{WaitForJobs: true})
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		completed := unstructuredFromYAML(t, jobComplete)
+		completed.SetResourceVersion(job.GetResourceVersion())
+		_ = fakeClient.Tracker().Update(gvr, completed, completed.GetNamespace())
+	}()
+
+	var seen []WaitEvent
+	for e := range events {
+		seen = append(seen, e)
+	}
+
+	require.NotEmpty(t, seen)
+	first := seen[0]
+	assert.Empty(t, first.PreviousStatus)
+	assert.NotEqual(t, status.CurrentStatus.String(), first.CurrentStatus)
+
+	last := seen[len(seen)-1]
+	assert.Equal(t, status.CurrentStatus.String(), last.CurrentStatus)
+	assert.NotEqual(t, last.PreviousStatus, last.CurrentStatus)
+}
+
+// TestKWaitStreamError proves that a watch failure is signaled to the
+// caller on the returned channel via WaitEvent.Err, rather than the channel
+// just closing silently as it would for a successful or cancelled wait.
+func TestKWaitStreamError(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t)
+	job := unstructuredFromYAML(t, jobNoStatus)
+	resourceList, err := c.Build(objBody(job), false)
+	require.NoError(t, err)
+
+	watchErr := errors.New("watch failed")
+	kwaiter := kstatusWaiter{sw: erroringStatusWatcher{err: watchErr}, log: log.Printf}
+
+	events, err := kwaiter.WaitStream(context.Background(), resourceList, WaitOptions{})
+	require.NoError(t, err)
+
+	var seen []WaitEvent
+	for e := range events {
+		seen = append(seen, e)
+	}
+
+	require.Len(t, seen, 1)
+	assert.Equal(t, watchErr, seen[0].Err)
+}